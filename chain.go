@@ -0,0 +1,83 @@
+package flagenv
+
+import "fmt"
+
+// Layer bundles a KeyFunc and LookupFunc for use with Chain, so a single Chain can consult sources
+// that each use a different key style (e.g. uppercased snake_case for env vars, lowercased
+// dot.case for a TOML file, and verbatim keys for an in-memory defaults map).
+type Layer struct {
+	// LayerName identifies this layer for provenance purposes (e.g. "env", "config.toml", or
+	// "defaults"). It has no effect on the lookup itself, but is passed to Chain.OnLookup so
+	// callers can tell which layer resolved a key.
+	LayerName string
+	// Key transforms the key Chain was queried with into this layer's own key style. If nil,
+	// the key is used unchanged.
+	Key KeyFunc
+	// Lookup is queried with the key Key produces. It may not be nil.
+	Lookup LookupFunc
+}
+
+// Chain combines a sequence of Layers into a single LookupFunc, letting a Loader consult several
+// differently-keyed sources without the caller having to normalize keys ahead of time.
+type Chain struct {
+	// Layers are queried in order.
+	Layers []Layer
+	// Merge, if true, concatenates the values from every layer that returns a non-empty slice
+	// instead of stopping at the first one. This is mainly useful for slice-typed flags, where
+	// each layer may contribute some of the values.
+	Merge bool
+	// OnLookup, if set, is called once for every layer whose Lookup returns a non-empty slice.
+	// Combined with LayerName, this gives the same kind of provenance Loader.OnSet provides for
+	// a Loader as a whole, but at the level of an individual Chain's layers.
+	OnLookup func(layer Layer, key, lookedUpKey string, values []string)
+}
+
+// LayeredLookup returns a LookupFunc backed by layers, equivalent to (&Chain{Layers: layers, Merge:
+// merge}).Lookup.
+func LayeredLookup(layers []Layer, merge bool) LookupFunc {
+	c := &Chain{Layers: layers, Merge: merge}
+	return c.Lookup
+}
+
+// Lookup implements LookupFunc for c by querying its Layers in order, returning the first
+// non-empty result or, if c.Merge is true, the concatenation of every non-empty result.
+func (c *Chain) Lookup(key string) ([]string, error) {
+	var values []string
+	for _, layer := range c.Layers {
+		if layer.Lookup == nil {
+			continue
+		}
+		keyfn := layer.Key
+		if keyfn == nil {
+			keyfn = Identity
+		}
+		lookedUpKey := keyfn(key)
+		if lookedUpKey == "" {
+			continue
+		}
+
+		v, err := layer.Lookup(lookedUpKey)
+		if err != nil {
+			return nil, fmt.Errorf("chain: layer %s: looking up %s: %w", layerName(layer), lookedUpKey, err)
+		}
+		if len(v) == 0 {
+			continue
+		}
+
+		if c.OnLookup != nil {
+			c.OnLookup(layer, key, lookedUpKey, v)
+		}
+		if !c.Merge {
+			return v, nil
+		}
+		values = append(values, v...)
+	}
+	return values, nil
+}
+
+func layerName(l Layer) string {
+	if l.LayerName != "" {
+		return l.LayerName
+	}
+	return "<unnamed>"
+}
@@ -0,0 +1,174 @@
+// Package fileconfig provides flagenv.LookupFunc constructors backed by
+// common on-disk configuration file formats. Each loader decodes its input
+// into a tree of values and flattens it to the same dot-separated keys a
+// flagenv.DotLoader would produce, so a TOML table [server.http] with a
+// port key is exposed as "server.http.port".
+package fileconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/hcl"
+	"github.com/magiconair/properties"
+	"gopkg.in/yaml.v3"
+
+	"go.spiff.io/flagenv"
+)
+
+// NewTOMLLookup decodes r as TOML and returns a LookupFunc over its
+// flattened keys.
+func NewTOMLLookup(r io.Reader) (flagenv.LookupFunc, error) {
+	var tree map[string]interface{}
+	if _, err := toml.NewDecoder(r).Decode(&tree); err != nil {
+		return nil, fmt.Errorf("fileconfig: decoding TOML: %w", err)
+	}
+	return mapLookup(tree), nil
+}
+
+// NewYAMLLookup decodes r as YAML and returns a LookupFunc over its
+// flattened keys.
+func NewYAMLLookup(r io.Reader) (flagenv.LookupFunc, error) {
+	var tree map[string]interface{}
+	if err := yaml.NewDecoder(r).Decode(&tree); err != nil {
+		return nil, fmt.Errorf("fileconfig: decoding YAML: %w", err)
+	}
+	return mapLookup(tree), nil
+}
+
+// NewJSONLookup decodes r as JSON and returns a LookupFunc over its
+// flattened keys.
+func NewJSONLookup(r io.Reader) (flagenv.LookupFunc, error) {
+	var tree map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&tree); err != nil {
+		return nil, fmt.Errorf("fileconfig: decoding JSON: %w", err)
+	}
+	return mapLookup(tree), nil
+}
+
+// NewHCLLookup decodes r as HCL and returns a LookupFunc over its
+// flattened keys.
+func NewHCLLookup(r io.Reader) (flagenv.LookupFunc, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("fileconfig: reading HCL: %w", err)
+	}
+	var tree map[string]interface{}
+	if err := hcl.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("fileconfig: decoding HCL: %w", err)
+	}
+	return mapLookup(tree), nil
+}
+
+// NewPropertiesLookup decodes r as Java-style properties and returns a
+// LookupFunc over its keys. Properties keys are already dot-separated by
+// convention, so no flattening is performed.
+func NewPropertiesLookup(r io.Reader) (flagenv.LookupFunc, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("fileconfig: reading properties: %w", err)
+	}
+	props, err := properties.Load(data, properties.UTF8)
+	if err != nil {
+		return nil, fmt.Errorf("fileconfig: decoding properties: %w", err)
+	}
+	values := make(map[string][]string, props.Len())
+	for _, key := range props.Keys() {
+		v, _ := props.Get(key)
+		values[key] = []string{v}
+	}
+	return flagenv.LookupMapValues(values), nil
+}
+
+// MergedLookup returns a LookupFunc that queries each lookup in order and
+// returns the first non-empty result. This lets callers layer file config
+// under env vars under CLI flags in a well-defined precedence order, e.g.:
+//
+//	flagenv.Loader{
+//		Lookup: fileconfig.MergedLookup(envLookup, fileLookup, defaultsLookup),
+//	}
+func MergedLookup(lookups ...flagenv.LookupFunc) flagenv.LookupFunc {
+	return func(key string) ([]string, error) {
+		for _, lookup := range lookups {
+			if lookup == nil {
+				continue
+			}
+			values, err := lookup(key)
+			if err != nil {
+				return nil, err
+			}
+			if len(values) != 0 {
+				return values, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+// mapLookup flattens a decoded configuration tree into dot-separated keys
+// and returns a LookupFunc backed by the result.
+func mapLookup(tree map[string]interface{}) flagenv.LookupFunc {
+	values := map[string][]string{}
+	flatten(values, "", tree)
+	return flagenv.LookupMapValues(values)
+}
+
+// flatten walks a decoded configuration tree, recording each leaf value (or
+// slice of leaf values) under its dot-separated key path.
+func flatten(dst map[string][]string, prefix string, v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range val {
+			flatten(dst, joinKey(prefix, k), sub)
+		}
+	case map[interface{}]interface{}:
+		for k, sub := range val {
+			flatten(dst, joinKey(prefix, fmt.Sprint(k)), sub)
+		}
+	case []interface{}:
+		for i, item := range val {
+			switch item.(type) {
+			case map[string]interface{}, map[interface{}]interface{}, []interface{}, []map[string]interface{}:
+				flatten(dst, joinKey(prefix, strconv.Itoa(i)), item)
+			default:
+				dst[prefix] = append(dst[prefix], toString(item))
+			}
+		}
+	case []map[string]interface{}:
+		// hashicorp/hcl represents every block, repeated or not, as a
+		// slice of maps, so a singleton slice (the common case for a
+		// block that appears once) is flattened transparently to keep
+		// "server.http.port" dot-separated as documented above.
+		// BurntSushi/toml uses the same shape for genuine TOML arrays
+		// of tables ([[server]]), where multiple entries are indexed
+		// as "server.0.name", "server.1.name", and so on.
+		if len(val) == 1 {
+			flatten(dst, prefix, val[0])
+			break
+		}
+		for i, item := range val {
+			flatten(dst, joinKey(prefix, strconv.Itoa(i)), item)
+		}
+	case nil:
+		// Skip nil leaves; there's nothing to expose for this key.
+	default:
+		dst[prefix] = append(dst[prefix], toString(val))
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func toString(v interface{}) string {
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(v)
+}
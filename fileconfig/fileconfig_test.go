@@ -0,0 +1,149 @@
+package fileconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.spiff.io/flagenv"
+)
+
+func TestFlatten(t *testing.T) {
+	tree := map[string]interface{}{
+		"server": map[string]interface{}{
+			"http": map[string]interface{}{
+				"port": 8080,
+			},
+			"hosts": []interface{}{"a", "b", "c"},
+		},
+		"debug": true,
+	}
+
+	want := map[string][]string{
+		"server.http.port": {"8080"},
+		"server.hosts":     {"a", "b", "c"},
+		"debug":            {"true"},
+	}
+
+	got := map[string][]string{}
+	flatten(got, "", tree)
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("flatten produced unexpected results (-want +got):\n%s", diff)
+	}
+}
+
+func TestMergedLookup(t *testing.T) {
+	empty := func(string) ([]string, error) { return nil, nil }
+	first := func(key string) ([]string, error) {
+		if key == "found" {
+			return []string{"first"}, nil
+		}
+		return nil, nil
+	}
+	second := func(key string) ([]string, error) {
+		return []string{"second"}, nil
+	}
+
+	lookup := MergedLookup(empty, first, second)
+
+	if got, err := lookup("found"); err != nil || !cmp.Equal(got, []string{"first"}) {
+		t.Fatalf("lookup(%q) = %v, %v; want [first], nil", "found", got, err)
+	}
+	if got, err := lookup("missing"); err != nil || !cmp.Equal(got, []string{"second"}) {
+		t.Fatalf("lookup(%q) = %v, %v; want [second], nil", "missing", got, err)
+	}
+
+	want := errors.New("boom")
+	failing := MergedLookup(func(string) ([]string, error) { return nil, want })
+	if _, err := failing("key"); !errors.Is(err, want) {
+		t.Fatalf("lookup error = %v; want %v", err, want)
+	}
+}
+
+func checkLookup(t *testing.T, lookup flagenv.LookupFunc, key string, want []string) {
+	t.Helper()
+	got, err := lookup(key)
+	if err != nil {
+		t.Fatalf("lookup(%q) error = %v", key, err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("lookup(%q) (-want +got):\n%s", key, diff)
+	}
+}
+
+func TestNewTOMLLookup(t *testing.T) {
+	const doc = `
+debug = true
+
+[server.http]
+port = 8080
+
+[[server.instance]]
+name = "a"
+
+[[server.instance]]
+name = "b"
+`
+	lookup, err := NewTOMLLookup(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("NewTOMLLookup: %v", err)
+	}
+	checkLookup(t, lookup, "debug", []string{"true"})
+	checkLookup(t, lookup, "server.http.port", []string{"8080"})
+	checkLookup(t, lookup, "server.instance.0.name", []string{"a"})
+	checkLookup(t, lookup, "server.instance.1.name", []string{"b"})
+}
+
+func TestNewYAMLLookup(t *testing.T) {
+	const doc = `
+debug: true
+server:
+  http:
+    port: 8080
+`
+	lookup, err := NewYAMLLookup(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("NewYAMLLookup: %v", err)
+	}
+	checkLookup(t, lookup, "debug", []string{"true"})
+	checkLookup(t, lookup, "server.http.port", []string{"8080"})
+}
+
+func TestNewJSONLookup(t *testing.T) {
+	const doc = `{"debug": true, "server": {"http": {"port": 8080}}}`
+	lookup, err := NewJSONLookup(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("NewJSONLookup: %v", err)
+	}
+	checkLookup(t, lookup, "debug", []string{"true"})
+	checkLookup(t, lookup, "server.http.port", []string{"8080"})
+}
+
+func TestNewHCLLookup(t *testing.T) {
+	const doc = `
+debug = true
+
+server "http" {
+	port = 8080
+}
+`
+	lookup, err := NewHCLLookup(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("NewHCLLookup: %v", err)
+	}
+	checkLookup(t, lookup, "debug", []string{"true"})
+	checkLookup(t, lookup, "server.http.port", []string{"8080"})
+}
+
+func TestNewPropertiesLookup(t *testing.T) {
+	const doc = "debug = true\nserver.http.port = 8080\n"
+	lookup, err := NewPropertiesLookup(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("NewPropertiesLookup: %v", err)
+	}
+	checkLookup(t, lookup, "debug", []string{"true"})
+	checkLookup(t, lookup, "server.http.port", []string{"8080"})
+}
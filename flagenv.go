@@ -25,6 +25,70 @@ type KeyFunc func(name string) string
 type Loader struct {
 	Key    KeyFunc
 	Lookup LookupFunc
+
+	// OnSet, if set, is called once for every flag the Loader assigns a value to from its
+	// LookupFunc, after the flag.FlagSet has been updated.
+	OnSet func(FlagEvent)
+
+	provenance map[string]Source
+}
+
+// Source identifies where a flag's current value came from, as recorded by SourceOf and
+// Provenance.
+type Source int
+
+const (
+	// SourceDefault is the Source of a flag that hasn't been set by the CLI or a Loader; it's
+	// still holding the value it was registered with.
+	SourceDefault Source = iota
+	// SourceCLI is the Source of a flag that was set on the command line before the Loader ran.
+	SourceCLI
+	// SourceLookup is the Source of a flag that a Loader set from its LookupFunc.
+	SourceLookup
+)
+
+// String returns the lowercase name of s, e.g. "default", "cli", or "lookup".
+func (s Source) String() string {
+	switch s {
+	case SourceCLI:
+		return "cli"
+	case SourceLookup:
+		return "lookup"
+	default:
+		return "default"
+	}
+}
+
+// FlagEvent describes a single flag assignment a Loader made from its LookupFunc, for use with
+// Loader.OnSet.
+type FlagEvent struct {
+	// Key is the flag's name.
+	Key string
+	// LookedUpKey is the key the Loader's KeyFunc produced and queried the LookupFunc with.
+	LookedUpKey string
+	// Values are the values the LookupFunc returned and that were applied to the flag.
+	Values []string
+	// Source is the Source of this assignment. It is always SourceLookup for events delivered
+	// through OnSet.
+	Source Source
+}
+
+// SourceOf reports where name's current value came from, as recorded by calls made through l.
+// SetAll and SetMissing replace the whole record; SetOne only updates the entry for the flag it
+// sets and keeps the rest. If name has no entry, or no call has been made yet, SourceOf returns
+// SourceDefault.
+func (l *Loader) SourceOf(name string) Source {
+	return l.provenance[name]
+}
+
+// Provenance returns a copy of the flag-name-to-Source mapping recorded by l, kept up to date by
+// SetAll, SetMissing and SetOne as described for SourceOf.
+func (l *Loader) Provenance() map[string]Source {
+	out := make(map[string]Source, len(l.provenance))
+	for name, src := range l.provenance {
+		out[name] = src
+	}
+	return out
 }
 
 func snakePrefix(str string) string {
@@ -90,7 +154,24 @@ func (l *Loader) SetOne(f *flag.FlagSet, name string) error {
 	if fv == nil {
 		return fmt.Errorf("flag not found: %s", name)
 	}
-	return l.setFlag(f, fv)
+	if l.provenance == nil {
+		l.provenance = map[string]Source{}
+	}
+	if wasSetByCLI(f, name) {
+		l.provenance[name] = SourceCLI
+	}
+	return l.setFlag(f, fv, l.provenance)
+}
+
+// wasSetByCLI reports whether name was set on f before this call, as seen by f.Visit. SetOne takes
+// that to mean the CLI set it; a flag set by an earlier Loader call is reported the same way.
+func wasSetByCLI(f *flag.FlagSet, name string) (ok bool) {
+	f.Visit(func(fv *flag.Flag) {
+		if fv.Name == name {
+			ok = true
+		}
+	})
+	return ok
 }
 
 // SetOne sets the value of a single flag in the FlagSet.
@@ -116,7 +197,7 @@ func SetMissing(f *flag.FlagSet) error {
 	return defaultLoader.SetMissing(f)
 }
 
-func (l *Loader) setFlag(f *flag.FlagSet, fv *flag.Flag) error {
+func (l *Loader) setFlag(f *flag.FlagSet, fv *flag.Flag, provenance map[string]Source) error {
 	if l.Lookup == nil {
 		return errNoLookup
 	}
@@ -138,14 +219,25 @@ func (l *Loader) setFlag(f *flag.FlagSet, fv *flag.Flag) error {
 			return fmt.Errorf("unable to load %s config from key %s: %w", name, key, err)
 		}
 	}
+	if len(values) > 0 {
+		provenance[name] = SourceLookup
+		if l.OnSet != nil {
+			l.OnSet(FlagEvent{Key: name, LookedUpKey: key, Values: values, Source: SourceLookup})
+		}
+	}
 	return nil
 }
 
 func (l *Loader) setFlags(f *flag.FlagSet, merge bool) (err error) {
+	provenance := map[string]Source{}
+	seen := flagNames{}
+	f.Visit(seen.visit)
+	for name := range seen {
+		provenance[name] = SourceCLI
+	}
+
 	visited := func(*flag.Flag) bool { return false }
 	if merge {
-		seen := flagNames{}
-		f.Visit(seen.visit)
 		visited = seen.visited
 	}
 
@@ -153,11 +245,12 @@ func (l *Loader) setFlags(f *flag.FlagSet, merge bool) (err error) {
 		if err != nil || visited(fv) || shouldSkip(fv.Value) {
 			return
 		}
-		if ferr := l.setFlag(f, fv); ferr != nil && err == nil {
+		if ferr := l.setFlag(f, fv, provenance); ferr != nil && err == nil {
 			err = ferr
 		}
 	})
 
+	l.provenance = provenance
 	return err
 }
 
@@ -275,7 +275,7 @@ func TestLoader(t *testing.T) {
 			},
 			SetFn: (*Loader).SetAll,
 			Loader: &Loader{
-				Key:    WithPrefix("app-", Lowercased(ignoreKeys(KebabCase, "Str", "Bool", "Strs"))),
+				Key:    ignoreKeys(WithPrefix("app-", Lowercased(KebabCase)), "Str", "Bool", "Strs"),
 				Lookup: WithIndexedLookup(LookupMapValue(simpleEnv), "-", 0),
 			},
 			Want: Flags{
@@ -481,6 +481,227 @@ func TestCaseFuncs(t *testing.T) {
 	}
 }
 
+func TestSplitWords(t *testing.T) {
+	type Case struct {
+		In   string
+		Want []string
+	}
+
+	cases := []Case{
+		{In: "", Want: nil},
+		{In: "HTTPPort", Want: []string{"HTTP", "Port"}},
+		{In: "parseXMLDoc", Want: []string{"parse", "XML", "Doc"}},
+		{In: "v2Handler", Want: []string{"v2", "Handler"}},
+		{In: "foo_bar-baz", Want: []string{"foo", "bar", "baz"}},
+		{In: " Foo**Bar Baz___", Want: []string{"Foo", "Bar", "Baz"}},
+	}
+
+	for _, c := range cases {
+		got := SplitWords(c.In)
+		if diff := cmp.Diff(c.Want, got); diff != "" {
+			t.Errorf("SplitWords(%q) produced unexpected results (-want +got):\n%s", c.In, diff)
+		}
+	}
+}
+
+func TestCaseWordsFuncs(t *testing.T) {
+	type Case struct {
+		Name string
+		Fn   KeyFunc
+		In   string
+		Want string
+	}
+
+	cases := []Case{
+		{Name: "SnakeCaseWords", Fn: SnakeCaseWords, In: "HTTPPort", Want: "HTTP_Port"},
+		{Name: "KebabCaseWords", Fn: KebabCaseWords, In: "HTTPPort", Want: "HTTP-Port"},
+		{Name: "DotCaseWords", Fn: DotCaseWords, In: "HTTPPort", Want: "HTTP.Port"},
+		{Name: "SnakeCaseWords/parseXMLDoc", Fn: SnakeCaseWords, In: "parseXMLDoc", Want: "parse_XML_Doc"},
+		{Name: "SnakeCaseWords/v2Handler", Fn: SnakeCaseWords, In: "v2Handler", Want: "v2_Handler"},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			got := c.Fn(c.In)
+			if got != c.Want {
+				t.Fatalf("%s(%q) = %q; want %q", c.Name, c.In, got, c.Want)
+			}
+		})
+	}
+}
+
+func TestUppercasedSnakeCaseWords(t *testing.T) {
+	keyfn := Uppercased(SnakeCaseWords)
+	if got, want := keyfn("HTTPPort"), "HTTP_PORT"; got != want {
+		t.Fatalf("Uppercased(SnakeCaseWords)(%q) = %q; want %q", "HTTPPort", got, want)
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	env := Env{
+		"Int": "256",
+		"Str": "Hello!",
+	}
+
+	l := &Loader{Lookup: LookupMapValue(env)}
+
+	var events []FlagEvent
+	l.OnSet = func(ev FlagEvent) { events = append(events, ev) }
+
+	f := flag.NewFlagSet("test", flag.PanicOnError)
+	f.Int("Int", 0, "")
+	f.String("Str", "", "")
+	f.Bool("Bool", false, "")
+
+	if err := f.Parse([]string{"-Int=1"}); err != nil {
+		t.Fatalf("Error parsing args: %v", err)
+	}
+
+	if err := l.SetMissing(f); err != nil {
+		t.Fatalf("Error setting flags: %v", err)
+	}
+
+	if got, want := l.SourceOf("Int"), SourceCLI; got != want {
+		t.Errorf("SourceOf(%q) = %v; want %v", "Int", got, want)
+	}
+	if got, want := l.SourceOf("Str"), SourceLookup; got != want {
+		t.Errorf("SourceOf(%q) = %v; want %v", "Str", got, want)
+	}
+	if got, want := l.SourceOf("Bool"), SourceDefault; got != want {
+		t.Errorf("SourceOf(%q) = %v; want %v", "Bool", got, want)
+	}
+
+	wantProvenance := map[string]Source{
+		"Int": SourceCLI,
+		"Str": SourceLookup,
+	}
+	if diff := cmp.Diff(wantProvenance, l.Provenance()); diff != "" {
+		t.Errorf("Provenance() differs from expected (-want +got):\n%s", diff)
+	}
+
+	wantEvents := []FlagEvent{
+		{Key: "Str", LookedUpKey: "Str", Values: []string{"Hello!"}, Source: SourceLookup},
+	}
+	if diff := cmp.Diff(wantEvents, events); diff != "" {
+		t.Errorf("OnSet events differ from expected (-want +got):\n%s", diff)
+	}
+}
+
+func TestProvenanceSetOne(t *testing.T) {
+	env := Env{"Str": "Hello!"}
+	l := &Loader{Lookup: LookupMapValue(env)}
+
+	f := flag.NewFlagSet("test", flag.PanicOnError)
+	f.Int("Int", 0, "")
+	f.String("Str", "", "")
+
+	if err := f.Parse([]string{"-Int=1"}); err != nil {
+		t.Fatalf("Error parsing args: %v", err)
+	}
+
+	// SetOne("Int") has nothing to find in the lookup, but Int was set on
+	// the command line, so its provenance must stay SourceCLI rather than
+	// go unrecorded as SourceDefault.
+	if err := l.SetOne(f, "Int"); err != nil {
+		t.Fatalf("SetOne(%q): %v", "Int", err)
+	}
+	if got, want := l.SourceOf("Int"), SourceCLI; got != want {
+		t.Errorf("SourceOf(%q) = %v; want %v", "Int", got, want)
+	}
+
+	if err := l.SetOne(f, "Str"); err != nil {
+		t.Fatalf("SetOne(%q): %v", "Str", err)
+	}
+	if got, want := l.SourceOf("Str"), SourceLookup; got != want {
+		t.Errorf("SourceOf(%q) = %v; want %v", "Str", got, want)
+	}
+}
+
+func TestChain(t *testing.T) {
+	envLayer := Layer{
+		LayerName: "env",
+		Key:       Uppercased(SnakeCase),
+		Lookup:    LookupMapValue(Env{"STR": "from-env", "STRS": "x"}),
+	}
+	fileLayer := Layer{
+		LayerName: "config.toml",
+		Key:       Lowercased(DotCase),
+		Lookup:    LookupMapValues(ValuesEnv{"str": {"from-file"}, "strs": {"a", "b"}}),
+	}
+	defaultsLayer := Layer{
+		LayerName: "defaults",
+		Lookup:    LookupMapValue(Env{"Str": "from-defaults", "Int": "1"}),
+	}
+
+	t.Run("FirstNonEmptyWins", func(t *testing.T) {
+		var resolved []string
+		c := &Chain{
+			Layers: []Layer{envLayer, fileLayer, defaultsLayer},
+			OnLookup: func(layer Layer, key, lookedUpKey string, values []string) {
+				resolved = append(resolved, layer.LayerName)
+			},
+		}
+
+		got, err := c.Lookup("Str")
+		if err != nil {
+			t.Fatalf("Lookup error: %v", err)
+		}
+		if want := []string{"from-env"}; !cmp.Equal(got, want) {
+			t.Errorf("Lookup(%q) = %v; want %v", "Str", got, want)
+		}
+		if want := []string{"env"}; !cmp.Equal(resolved, want) {
+			t.Errorf("OnLookup layers = %v; want %v", resolved, want)
+		}
+
+		// Int isn't in the env or file layers, so it falls through to defaults.
+		got, err = c.Lookup("Int")
+		if err != nil {
+			t.Fatalf("Lookup error: %v", err)
+		}
+		if want := []string{"1"}; !cmp.Equal(got, want) {
+			t.Errorf("Lookup(%q) = %v; want %v", "Int", got, want)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		c := &Chain{
+			Layers: []Layer{envLayer, fileLayer},
+			Merge:  true,
+		}
+
+		got, err := c.Lookup("Strs")
+		if err != nil {
+			t.Fatalf("Lookup error: %v", err)
+		}
+		if want := []string{"x", "a", "b"}; !cmp.Equal(got, want) {
+			t.Errorf("Lookup(%q) = %v; want %v", "Strs", got, want)
+		}
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		c := &Chain{Layers: []Layer{envLayer, fileLayer, defaultsLayer}}
+		got, err := c.Lookup("NotAKey")
+		if err != nil {
+			t.Fatalf("Lookup error: %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("Lookup(%q) = %v; want empty", "NotAKey", got)
+		}
+	})
+
+	t.Run("LayerError", func(t *testing.T) {
+		want := errors.New("boom")
+		c := &Chain{Layers: []Layer{{
+			LayerName: "broken",
+			Lookup:    func(string) ([]string, error) { return nil, want },
+		}}}
+		if _, err := c.Lookup("Str"); !errors.Is(err, want) {
+			t.Errorf("Lookup error = %v; want it to wrap %v", err, want)
+		}
+	})
+}
+
 func TestLookupError(t *testing.T) {
 	f := flag.NewFlagSet("test", flag.ContinueOnError)
 	_ = f.Int("Int", 0, "")
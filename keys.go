@@ -5,6 +5,12 @@ import (
 	"unicode"
 )
 
+// Identity is a KeyFunc that returns name unchanged. It's the default KeyFunc used by Loader and
+// Chain when none is set.
+func Identity(name string) string {
+	return name
+}
+
 // WithPrefix returns a KeyFunc that prefixes all keys with the given prefix string before passing
 // them to the keyfn.
 func WithPrefix(prefix string, keyfn KeyFunc) KeyFunc {
@@ -89,3 +95,97 @@ func KebabCase(name string) string {
 	}
 	return strings.Map(runeMap, name)
 }
+
+// WordSplitter reports whether a word boundary belongs immediately before curr, given the
+// alphanumeric rune that precedes it. It's used by SplitWordsFunc (and, through it, SplitWords and
+// the *Words KeyFuncs) to decide where to break an identifier into words.
+type WordSplitter func(prev, curr rune) bool
+
+// DefaultWordSplitter is the WordSplitter used by SplitWords. It reports a boundary on any
+// non-alphanumeric rune and on transitions from a lowercase letter to an uppercase letter (aA) or
+// from a digit to a letter (2a, 2A).
+func DefaultWordSplitter(prev, curr rune) bool {
+	switch {
+	case !isAlnum(prev), !isAlnum(curr):
+		return true
+	case unicode.IsUpper(curr) && unicode.IsLower(prev):
+		return true
+	case unicode.IsLetter(curr) && unicode.IsDigit(prev):
+		return true
+	default:
+		return false
+	}
+}
+
+// SplitWords splits name into words using DefaultWordSplitter, additionally keeping runs of
+// uppercase letters together as acronyms: a run of uppercase letters immediately followed by a
+// lowercase letter is split before its last letter, so the acronym and the Title-cased word after
+// it come out as separate words (e.g. "parseXMLDoc" -> ["parse", "XML", "Doc"]). Non-alphanumeric
+// runes are treated as separators and dropped: a run of them is a single word boundary, and leading
+// or trailing ones produce no empty words (unlike SnakeCase and friends, which keep one separator
+// in their place).
+func SplitWords(name string) []string {
+	return SplitWordsFunc(name, DefaultWordSplitter)
+}
+
+// SplitWordsFunc splits name into words the same way SplitWords does, but using split to decide
+// word boundaries instead of DefaultWordSplitter. This lets callers plug in Unicode-aware or
+// acronym-list-driven splitters while keeping the acronym-then-Title-word handling SplitWords does.
+func SplitWordsFunc(name string, split WordSplitter) []string {
+	if split == nil {
+		split = DefaultWordSplitter
+	}
+
+	runes := []rune(name)
+	var words []string
+	start := -1
+
+	flush := func(end int) {
+		if start >= 0 && start < end {
+			words = append(words, string(runes[start:end]))
+		}
+		start = -1
+	}
+
+	for i, r := range runes {
+		if !isAlnum(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		boundary := split(prev, r)
+		if !boundary && unicode.IsUpper(prev) && unicode.IsUpper(r) &&
+			i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			boundary = true
+		}
+		if boundary {
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+
+	return words
+}
+
+// SnakeCaseWords is a KeyFunc that converts names to snake_case using SplitWords to find word
+// boundaries, keeping acronyms intact (e.g. "HTTPPort" -> "HTTP_Port" rather than "H_T_T_P_Port").
+func SnakeCaseWords(name string) string {
+	return strings.Join(SplitWords(name), "_")
+}
+
+// DotCaseWords is a KeyFunc that converts names to dot.case using SplitWords to find word
+// boundaries, keeping acronyms intact (e.g. "HTTPPort" -> "HTTP.Port" rather than "H.T.T.P.Port").
+func DotCaseWords(name string) string {
+	return strings.Join(SplitWords(name), ".")
+}
+
+// KebabCaseWords is a KeyFunc that converts names to kebab-case using SplitWords to find word
+// boundaries, keeping acronyms intact (e.g. "HTTPPort" -> "HTTP-Port" rather than "H-T-T-P-Port").
+func KebabCaseWords(name string) string {
+	return strings.Join(SplitWords(name), "-")
+}
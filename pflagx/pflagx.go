@@ -0,0 +1,147 @@
+// Package pflagx mirrors flagenv's Loader against github.com/spf13/pflag
+// FlagSets, for applications built on Cobra/pflag rather than the standard
+// library's flag package.
+package pflagx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"go.spiff.io/flagenv"
+)
+
+var errNoLookup = errors.New("no lookup function defined")
+
+// KeyFunc transforms a pflag flag into a key for use with a
+// flagenv.LookupFunc. Unlike flagenv.KeyFunc, it receives the full
+// *pflag.Flag, since pflag distinguishes long names from shorthands in a way
+// a bare name doesn't capture.
+type KeyFunc func(f *pflag.Flag) string
+
+// PflagKey adapts a flagenv.KeyFunc to pflagx's KeyFunc by applying it to the
+// flag's long name (f.Name), ignoring any shorthand.
+func PflagKey(keyfn flagenv.KeyFunc) KeyFunc {
+	return func(f *pflag.Flag) string {
+		return keyfn(f.Name)
+	}
+}
+
+func identity(f *pflag.Flag) string {
+	return f.Name
+}
+
+// Loader configures a pflag-aware loader to use particular Key and Lookup
+// functions, mirroring flagenv.Loader.
+//
+// The Lookup function may not be nil. If it is, it will return an error.
+type Loader struct {
+	Key    KeyFunc
+	Lookup flagenv.LookupFunc
+}
+
+// SetAll sets all flags in the FlagSet.
+//
+// If a flag's value has a method `SkipMerge() bool` that returns true, then
+// that flag is ignored by the Loader.
+func (l *Loader) SetAll(f *pflag.FlagSet) error {
+	return l.setFlags(f, false)
+}
+
+// SetOne sets the value of a single flag in the FlagSet.
+// It returns an error if the flag doesn't exist.
+func (l *Loader) SetOne(f *pflag.FlagSet, name string) error {
+	fv := f.Lookup(name)
+	if fv == nil {
+		return fmt.Errorf("flag not found: %s", name)
+	}
+	return l.setFlag(f, fv)
+}
+
+// SetMissing sets all flags that weren't already seen by the FlagSet.
+//
+// If a flag's value has a method `SkipMerge() bool` that returns true, then
+// that flag is ignored by the Loader.
+func (l *Loader) SetMissing(f *pflag.FlagSet) error {
+	return l.setFlags(f, true)
+}
+
+func (l *Loader) setFlag(f *pflag.FlagSet, fv *pflag.Flag) error {
+	if l.Lookup == nil {
+		return errNoLookup
+	}
+	keyfn := l.Key
+	if keyfn == nil {
+		keyfn = identity
+	}
+	key := keyfn(fv)
+	if key == "" {
+		return nil
+	}
+	values, err := l.Lookup(key)
+	if err != nil {
+		return fmt.Errorf("error looking up %s config with key %s: %w", fv.Name, key, err)
+	}
+	if err := setValues(f, fv, values); err != nil {
+		return fmt.Errorf("unable to load %s config from key %s: %w", fv.Name, key, err)
+	}
+	return nil
+}
+
+// setValues applies values to fv. Slice-typed pflag values (StringSlice,
+// StringArray, IntSlice, and so on) implement pflag.SliceValue, whose Set
+// method appends once a flag has already been set rather than replacing its
+// contents, so calling it once per looked-up value can silently merge with
+// whatever the flag already held (e.g. a prior CLI occurrence). setValues
+// instead uses SliceValue.Replace to assign the full set of looked-up values
+// at once.
+func setValues(f *pflag.FlagSet, fv *pflag.Flag, values []string) error {
+	if slice, ok := fv.Value.(pflag.SliceValue); ok {
+		if len(values) == 0 {
+			return nil
+		}
+		return slice.Replace(values)
+	}
+	for _, value := range values {
+		if err := f.Set(fv.Name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Loader) setFlags(f *pflag.FlagSet, merge bool) (err error) {
+	visited := map[string]struct{}{}
+	if merge {
+		f.Visit(func(fv *pflag.Flag) {
+			visited[fv.Name] = struct{}{}
+		})
+	}
+
+	f.VisitAll(func(fv *pflag.Flag) {
+		if err != nil {
+			return
+		}
+		if _, seen := visited[fv.Name]; seen {
+			return
+		}
+		if shouldSkip(fv.Value) {
+			return
+		}
+		if ferr := l.setFlag(f, fv); ferr != nil {
+			err = ferr
+		}
+	})
+
+	return err
+}
+
+type mergeSkipper interface {
+	SkipMerge() bool
+}
+
+func shouldSkip(v pflag.Value) bool {
+	ms, ok := v.(mergeSkipper)
+	return ok && ms.SkipMerge()
+}
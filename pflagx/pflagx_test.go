@@ -0,0 +1,108 @@
+package pflagx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/pflag"
+
+	"go.spiff.io/flagenv"
+)
+
+func TestLoader(t *testing.T) {
+	env := map[string][]string{
+		"str":  {"hello"},
+		"strs": {"a", "b", "c"},
+	}
+
+	type Flags struct {
+		Str  string
+		Strs []string
+	}
+
+	type Case struct {
+		Name  string
+		SetFn func(l *Loader, f *pflag.FlagSet) error
+		Args  []string
+		Want  Flags
+	}
+
+	cases := []Case{
+		{
+			Name:  "SetMissing-NoFlagsPassed",
+			SetFn: (*Loader).SetMissing,
+			Want: Flags{
+				Str:  "hello",
+				Strs: []string{"a", "b", "c"},
+			},
+		},
+		{
+			Name:  "SetMissing-FlagsAlreadySet",
+			SetFn: (*Loader).SetMissing,
+			Args:  []string{"--str=cli", "--strs=x"},
+			Want: Flags{
+				Str:  "cli",
+				Strs: []string{"x"},
+			},
+		},
+		{
+			Name:  "SetAll-Overrides",
+			SetFn: (*Loader).SetAll,
+			Args:  []string{"--str=cli", "--strs=x"},
+			Want: Flags{
+				Str:  "hello",
+				Strs: []string{"a", "b", "c"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			l := &Loader{Lookup: flagenv.LookupMapValues(env)}
+
+			f := pflag.NewFlagSet(c.Name, pflag.ContinueOnError)
+			got := Flags{}
+			f.StringVar(&got.Str, "str", "", "")
+			f.StringSliceVar(&got.Strs, "strs", nil, "")
+
+			if err := f.Parse(c.Args); err != nil {
+				t.Fatalf("Error parsing args: %v", err)
+			}
+
+			if err := c.SetFn(l, f); err != nil {
+				t.Fatalf("Error setting flags: %v", err)
+			}
+
+			if diff := cmp.Diff(c.Want, got); diff != "" {
+				t.Fatalf("Loaded values differ from expected values (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.Int("int", 0, "")
+
+	l := &Loader{}
+	if err := l.SetAll(f); err != errNoLookup {
+		t.Errorf("Loader without Lookup returned %#v; want %#v", err, errNoLookup)
+	}
+
+	if err := l.SetOne(f, "not-a-flag"); err == nil {
+		t.Error("SetOne did not return an error for an undefined flag when one was expected")
+	}
+}
+
+func TestPflagKey(t *testing.T) {
+	keyfn := PflagKey(flagenv.Uppercased(flagenv.SnakeCase))
+
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.StringP("worker-user", "u", "", "")
+	fv := f.Lookup("worker-user")
+
+	if got, want := keyfn(fv), "WORKER_USER"; got != want {
+		t.Errorf("keyfn(%q) = %q; want %q", fv.Name, got, want)
+	}
+}
@@ -0,0 +1,226 @@
+// Package watch adds live-reload support to flagenv by re-running a Loader
+// whenever one of its backing files changes on disk.
+//
+// Do the initial load of a watched FlagSet through WatchingLoader.Load
+// rather than calling the underlying Loader's SetMissing/SetAll directly;
+// see Load for why.
+package watch
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"go.spiff.io/flagenv"
+)
+
+// defaultDebounce is the window used to coalesce rapid write/rename/create
+// events for the same file, such as the rename-then-write sequence many
+// editors use when saving.
+const defaultDebounce = 250 * time.Millisecond
+
+// Event describes a reload triggered by a file change.
+type Event struct {
+	// Keys holds the names of the flags whose values changed as a result
+	// of the reload.
+	Keys []string
+	// FlagSet is the FlagSet that was reloaded.
+	FlagSet *flag.FlagSet
+}
+
+// WatchingLoader wraps a flagenv.Loader backed by one or more files, reloading
+// a FlagSet's values whenever those files change.
+type WatchingLoader struct {
+	// Loader performs the lookup and flag assignment on every reload. Its
+	// Lookup function is expected to read from the watched files.
+	Loader *flagenv.Loader
+	// FlagSet is the FlagSet reloaded on every change.
+	FlagSet *flag.FlagSet
+	// Merge, if true, reloads using Loader.SetAll, so file changes
+	// override flags already set by the CLI. If false, every reload
+	// leaves alone the flags that were set before the first reload.
+	Merge bool
+	// Debounce is the coalescing window for rapid successive events. If
+	// zero, defaultDebounce is used.
+	Debounce time.Duration
+	// OnChange, if set, is called once for every flag whose value changed
+	// as a result of a reload.
+	OnChange func(f *flag.Flag, oldValues, newValues []string)
+
+	// cliFlags records which flags were set on the command line before the
+	// first reload, so later reloads can keep honoring them even with
+	// Merge false. It's captured once, on the first call to reload,
+	// rather than re-derived from FlagSet.Visit on every reload: once a
+	// reload has called f.Set on a flag, Visit reports that flag as seen
+	// too, which would otherwise make every reload after the first look
+	// like the flag was always CLI-set.
+	cliFlags     map[string]struct{}
+	cliFlagsInit bool
+}
+
+// mergeSkipper is satisfied by a flag.Value whose SkipMerge method reports
+// true to opt out of Loader-driven assignment, mirroring the same check
+// flagenv.Loader.SetMissing/SetAll make internally.
+type mergeSkipper interface {
+	SkipMerge() bool
+}
+
+func shouldSkip(v flag.Value) bool {
+	ms, ok := v.(mergeSkipper)
+	return ok && ms.SkipMerge()
+}
+
+// Load performs FlagSet's initial load from Loader and reports whether it
+// changed any flag, recording which flags were already set on the command
+// line so later reloads (through Watch) keep honoring them even with Merge
+// false.
+//
+// Callers that want FlagSet populated before Watch's first file event —
+// e.g. to have config available immediately at startup — must do that
+// initial load through Load, not by calling Loader.SetMissing/SetAll on
+// FlagSet directly: Load (like reload) can only tell a CLI-set flag apart
+// from one this loader already populated itself by looking at FlagSet.Visit
+// the first time it runs, so a Loader call made before it would be
+// misread as the flag having come from the CLI, and file changes to that
+// flag would be silently ignored forever after.
+func (w *WatchingLoader) Load() (Event, bool) {
+	return w.reload()
+}
+
+// Watch watches paths for changes and reloads WatchingLoader's FlagSet
+// whenever one of them changes, emitting an Event for each reload that
+// actually changed a flag's value. The returned channel is closed once ctx
+// is canceled or the watcher fails irrecoverably.
+func (w *WatchingLoader) Watch(ctx context.Context, paths ...string) (<-chan Event, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("watch: creating watcher: %w", err)
+	}
+	for _, p := range paths {
+		if err := watcher.Add(p); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("watch: watching %s: %w", p, err)
+		}
+	}
+
+	events := make(chan Event)
+	go w.run(ctx, watcher, events)
+	return events, nil
+}
+
+func (w *WatchingLoader) run(ctx context.Context, watcher *fsnotify.Watcher, events chan<- Event) {
+	defer close(events)
+	defer watcher.Close()
+
+	debounce := w.Debounce
+	if debounce <= 0 {
+		debounce = defaultDebounce
+	}
+
+	var timer *time.Timer
+	var pending <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if timer == nil {
+				timer = time.NewTimer(debounce)
+			} else if !timer.Stop() && pending != nil {
+				// Drain only a fire the pending case hasn't received yet.
+				<-timer.C
+			}
+			timer.Reset(debounce)
+			pending = timer.C
+
+		case <-pending:
+			pending = nil
+			if ev, changed := w.reload(); changed {
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// reload re-runs Loader against FlagSet and reports which flags changed.
+func (w *WatchingLoader) reload() (Event, bool) {
+	before := snapshot(w.FlagSet)
+
+	if !w.cliFlagsInit {
+		w.cliFlags = map[string]struct{}{}
+		w.FlagSet.Visit(func(f *flag.Flag) {
+			w.cliFlags[f.Name] = struct{}{}
+		})
+		w.cliFlagsInit = true
+	}
+
+	var err error
+	if w.Merge {
+		err = w.Loader.SetAll(w.FlagSet)
+	} else {
+		// Loader.SetMissing re-derives "already set" from FlagSet.Visit,
+		// which would only ever apply once a flag this loader previously
+		// set from its lookup would then look CLI-set forever after. Use
+		// the CLI-only snapshot from the loader's first reload instead.
+		w.FlagSet.VisitAll(func(f *flag.Flag) {
+			if err != nil {
+				return
+			}
+			if _, ok := w.cliFlags[f.Name]; ok {
+				return
+			}
+			if shouldSkip(f.Value) {
+				return
+			}
+			if serr := w.Loader.SetOne(w.FlagSet, f.Name); serr != nil {
+				err = serr
+			}
+		})
+	}
+	if err != nil {
+		return Event{}, false
+	}
+
+	var keys []string
+	w.FlagSet.VisitAll(func(f *flag.Flag) {
+		oldValue, seen := before[f.Name]
+		newValue := f.Value.String()
+		if seen && oldValue == newValue {
+			return
+		}
+		keys = append(keys, f.Name)
+		if w.OnChange != nil {
+			w.OnChange(f, []string{oldValue}, []string{newValue})
+		}
+	})
+	if len(keys) == 0 {
+		return Event{}, false
+	}
+	return Event{Keys: keys, FlagSet: w.FlagSet}, true
+}
+
+// snapshot records the current string value of every flag in f.
+func snapshot(f *flag.FlagSet) map[string]string {
+	values := make(map[string]string)
+	f.VisitAll(func(fl *flag.Flag) {
+		values[fl.Name] = fl.Value.String()
+	})
+	return values
+}
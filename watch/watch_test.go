@@ -0,0 +1,179 @@
+package watch
+
+import (
+	"flag"
+	"testing"
+
+	"go.spiff.io/flagenv"
+)
+
+func TestWatchingLoaderReload(t *testing.T) {
+	values := map[string]string{"Str": "first"}
+
+	loader := &flagenv.Loader{
+		Lookup: flagenv.LookupMapValue(values),
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	str := fs.String("Str", "", "")
+
+	var changed []string
+	wl := &WatchingLoader{
+		Loader:  loader,
+		FlagSet: fs,
+		Merge:   true,
+		OnChange: func(f *flag.Flag, oldValues, newValues []string) {
+			changed = append(changed, f.Name)
+		},
+	}
+
+	// First reload sets the value and should report a change from "" to "first".
+	ev, ok := wl.reload()
+	if !ok {
+		t.Fatal("reload() reported no change on first load")
+	}
+	if *str != "first" {
+		t.Fatalf("Str = %q; want %q", *str, "first")
+	}
+	if len(ev.Keys) != 1 || ev.Keys[0] != "Str" {
+		t.Fatalf("Keys = %v; want [Str]", ev.Keys)
+	}
+	if len(changed) != 1 || changed[0] != "Str" {
+		t.Fatalf("OnChange calls = %v; want [Str]", changed)
+	}
+
+	// A reload with no change in the backing values should report nothing.
+	changed = nil
+	if _, ok := wl.reload(); ok {
+		t.Fatal("reload() reported a change when nothing changed")
+	}
+	if len(changed) != 0 {
+		t.Fatalf("OnChange calls = %v; want none", changed)
+	}
+
+	// Changing the backing value should be picked up on the next reload.
+	values["Str"] = "second"
+	ev, ok = wl.reload()
+	if !ok {
+		t.Fatal("reload() reported no change after value update")
+	}
+	if *str != "second" {
+		t.Fatalf("Str = %q; want %q", *str, "second")
+	}
+	if len(ev.Keys) != 1 || ev.Keys[0] != "Str" {
+		t.Fatalf("Keys = %v; want [Str]", ev.Keys)
+	}
+}
+
+func TestWatchingLoaderReloadNoMerge(t *testing.T) {
+	values := map[string]string{"Str": "first"}
+
+	loader := &flagenv.Loader{
+		Lookup: flagenv.LookupMapValue(values),
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	str := fs.String("Str", "", "")
+
+	wl := &WatchingLoader{
+		Loader:  loader,
+		FlagSet: fs,
+		// Merge defaults to false: reload should keep applying changes
+		// from the backing lookup to a flag it previously set itself.
+	}
+
+	if _, ok := wl.reload(); !ok {
+		t.Fatal("reload() reported no change on first load")
+	}
+	if *str != "first" {
+		t.Fatalf("Str = %q; want %q", *str, "first")
+	}
+
+	// A second change to the same flag must still be picked up, even
+	// though the first reload already called f.Set on it.
+	values["Str"] = "second"
+	if _, ok := wl.reload(); !ok {
+		t.Fatal("reload() reported no change after second value update")
+	}
+	if *str != "second" {
+		t.Fatalf("Str = %q; want %q", *str, "second")
+	}
+}
+
+func TestWatchingLoaderLoadThenReload(t *testing.T) {
+	values := map[string]string{"Str": "first"}
+
+	loader := &flagenv.Loader{
+		Lookup: flagenv.LookupMapValue(values),
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	str := fs.String("Str", "", "")
+
+	wl := &WatchingLoader{
+		Loader:  loader,
+		FlagSet: fs,
+	}
+
+	// The startup load goes through WatchingLoader.Load, not
+	// loader.SetMissing directly, so it doesn't get mistaken for a
+	// CLI-set value and frozen there.
+	if _, ok := wl.Load(); !ok {
+		t.Fatal("Load() reported no change on startup load")
+	}
+	if *str != "first" {
+		t.Fatalf("Str = %q; want %q", *str, "first")
+	}
+
+	values["Str"] = "second"
+	if _, ok := wl.reload(); !ok {
+		t.Fatal("reload() reported no change after first file change")
+	}
+	if *str != "second" {
+		t.Fatalf("Str = %q; want %q", *str, "second")
+	}
+
+	values["Str"] = "third"
+	if _, ok := wl.reload(); !ok {
+		t.Fatal("reload() reported no change after second file change")
+	}
+	if *str != "third" {
+		t.Fatalf("Str = %q; want %q", *str, "third")
+	}
+}
+
+func TestWatchingLoaderReloadNoMergePreservesCLI(t *testing.T) {
+	values := map[string]string{"Str": "first"}
+
+	loader := &flagenv.Loader{
+		Lookup: flagenv.LookupMapValue(values),
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	str := fs.String("Str", "", "")
+	if err := fs.Set("Str", "cli"); err != nil {
+		t.Fatalf("fs.Set: %v", err)
+	}
+
+	wl := &WatchingLoader{
+		Loader:  loader,
+		FlagSet: fs,
+	}
+
+	// CLI-set flags must never be overridden when Merge is false, on the
+	// first reload or any later one.
+	if _, ok := wl.reload(); ok {
+		t.Fatal("reload() reported a change for a CLI-set flag")
+	}
+	if *str != "cli" {
+		t.Fatalf("Str = %q; want %q", *str, "cli")
+	}
+
+	values["Str"] = "second"
+	if _, ok := wl.reload(); ok {
+		t.Fatal("reload() reported a change for a CLI-set flag after a later update")
+	}
+	if *str != "cli" {
+		t.Fatalf("Str = %q; want %q", *str, "cli")
+	}
+}